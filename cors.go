@@ -0,0 +1,88 @@
+package lumbimux
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSOptions configura CORSMiddleware. Como con cualquier otro Middleware, puede
+// aplicarse a una ruta concreta, a un Group (afectando solo a ese prefijo) o al router
+// completo mediante Use, según dónde haga falta el allow-list.
+type CORSOptions struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	AllowCredentials bool
+	// MaxAge son los segundos que el navegador puede cachear la respuesta a un preflight.
+	MaxAge int
+}
+
+// permiteOrigen indica si origen está en la lista blanca de opts, aceptando "*" como
+// comodín para cualquier origen.
+func (o CORSOptions) permiteOrigen(origen string) bool {
+	if origen == "" {
+		return false
+	}
+	for _, permitido := range o.AllowOrigins {
+		if permitido == "*" || permitido == origen {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware añade las cabeceras CORS correspondientes a opts y responde
+// automáticamente a las solicitudes de preflight (OPTIONS con
+// Access-Control-Request-Method). Aplicado con Use o Group no hace falta registrar una
+// ruta OPTIONS aparte. Si se pasa solo al registrar un método concreto de una ruta, el
+// preflight no pasa por él salvo que se registre también un handler OPTIONS con este
+// middleware: la respuesta automática de ServeHTTP solo aplica los middlewares de Use/Group.
+func CORSMiddleware(opts CORSOptions) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			origen := r.Header.Get("Origin")
+			if !opts.permiteOrigen(origen) {
+				next(w, r)
+				return
+			}
+
+			cabeceras := w.Header()
+			if contiene(opts.AllowOrigins, "*") && !opts.AllowCredentials {
+				cabeceras.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				cabeceras.Set("Access-Control-Allow-Origin", origen)
+				cabeceras.Add("Vary", "Origin")
+			}
+			if opts.AllowCredentials {
+				cabeceras.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			esPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !esPreflight {
+				next(w, r)
+				return
+			}
+
+			if len(opts.AllowMethods) > 0 {
+				cabeceras.Set("Access-Control-Allow-Methods", strings.Join(opts.AllowMethods, ", "))
+			}
+			if len(opts.AllowHeaders) > 0 {
+				cabeceras.Set("Access-Control-Allow-Headers", strings.Join(opts.AllowHeaders, ", "))
+			}
+			if opts.MaxAge > 0 {
+				cabeceras.Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func contiene(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
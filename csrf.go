@@ -0,0 +1,164 @@
+package lumbimux
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// tokenKeyType es el tipo usado como clave de contexto para el token CSRF vigente,
+// evitando colisiones con otras claves de tipo string.
+type tokenKeyType struct{}
+
+var tokenKey = tokenKeyType{}
+
+// CSRFOptions configura CSRFMiddleware: el secreto usado para firmar el token y los
+// atributos de la cookie donde se guarda, en la línea de nosurf.SetBaseCookie.
+type CSRFOptions struct {
+	// Secret firma el token para que no pueda falsificarse sin conocerlo. Obligatorio.
+	Secret []byte
+	// CookieName es el nombre de la cookie donde se guarda el token. Por defecto
+	// "csrf_token".
+	CookieName string
+	Domain     string
+	Path       string
+	MaxAge     int
+	HttpOnly   bool
+	Secure     bool
+	// HeaderName es la cabecera que se acepta como portador del token en peticiones que
+	// modifican estado. Por defecto "X-CSRF-Token".
+	HeaderName string
+	// FieldName es el campo de formulario alternativo a HeaderName. Por defecto
+	// "csrf_token".
+	FieldName string
+}
+
+func (o CSRFOptions) conDefaults() CSRFOptions {
+	if o.CookieName == "" {
+		o.CookieName = "csrf_token"
+	}
+	if o.Path == "" {
+		o.Path = "/"
+	}
+	if o.HeaderName == "" {
+		o.HeaderName = "X-CSRF-Token"
+	}
+	if o.FieldName == "" {
+		o.FieldName = "csrf_token"
+	}
+	return o
+}
+
+var metodosSeguros = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// CSRFMiddleware protege las rutas que modifican estado (POST, PUT, PATCH, DELETE)
+// frente a Cross-Site Request Forgery. Emite un token firmado en una cookie
+// configurable mediante opts y, para GET/HEAD/OPTIONS, se limita a renovarlo. Para el
+// resto de métodos exige que el valor de la cookie coincida con el enviado en el header
+// opts.HeaderName o en el campo de formulario opts.FieldName.
+func CSRFMiddleware(opts CSRFOptions) Middleware {
+	opts = opts.conDefaults()
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := tokenDeCookie(r, opts)
+			if token == "" {
+				token = generaToken(opts.Secret)
+			}
+			setBaseCookie(w, opts, token)
+			ctx := context.WithValue(r.Context(), tokenKey, token)
+			r = r.WithContext(ctx)
+
+			if metodosSeguros[r.Method] {
+				next(w, r)
+				return
+			}
+
+			enviado := r.Header.Get(opts.HeaderName)
+			if enviado == "" {
+				enviado = r.FormValue(opts.FieldName)
+			}
+			if enviado == "" || !hmac.Equal([]byte(enviado), []byte(token)) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// TokenFrom devuelve el token CSRF vigente para la solicitud actual, para poder
+// incrustarlo en formularios desde las plantillas.
+func TokenFrom(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey).(string)
+	return token
+}
+
+// tokenDeCookie recupera el token de la cookie de la solicitud si existe y su firma es
+// válida, descartándolo en caso contrario para que se genere uno nuevo.
+func tokenDeCookie(r *http.Request, opts CSRFOptions) string {
+	cookie, err := r.Cookie(opts.CookieName)
+	if err != nil || !tokenValido(cookie.Value, opts.Secret) {
+		return ""
+	}
+	return cookie.Value
+}
+
+// setBaseCookie escribe la cookie del token CSRF con los atributos de opts, de forma
+// análoga a nosurf.SetBaseCookie.
+func setBaseCookie(w http.ResponseWriter, opts CSRFOptions, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     opts.CookieName,
+		Value:    token,
+		Domain:   opts.Domain,
+		Path:     opts.Path,
+		MaxAge:   opts.MaxAge,
+		HttpOnly: opts.HttpOnly,
+		Secure:   opts.Secure,
+	})
+}
+
+// generaToken crea un token aleatorio de 32 bytes y lo firma con secret.
+func generaToken(secret []byte) string {
+	valor := make([]byte, 32)
+	if _, err := rand.Read(valor); err != nil {
+		panic(err)
+	}
+	return firma(valor, secret)
+}
+
+// firma codifica valor en base64 y le añade una firma HMAC-SHA256 separada por un punto.
+func firma(valor, secret []byte) string {
+	codificado := base64.RawURLEncoding.EncodeToString(valor)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(codificado))
+	return codificado + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// tokenValido comprueba que token tenga el formato "valor.firma" y que la firma
+// corresponda a valor bajo secret.
+func tokenValido(token string, secret []byte) bool {
+	partes := strings.SplitN(token, ".", 2)
+	if len(partes) != 2 {
+		return false
+	}
+	esperado := firma(decodifica(partes[0]), secret)
+	return hmac.Equal([]byte(esperado), []byte(token))
+}
+
+func decodifica(valor string) []byte {
+	datos, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return nil
+	}
+	return datos
+}
@@ -0,0 +1,79 @@
+package lumbimux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRFMiddlewareEmiteYValidaToken(t *testing.T) {
+	opts := CSRFOptions{Secret: []byte("secreto-de-prueba")}
+	handler := CSRFMiddleware(opts)(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(TokenFrom(r.Context())))
+	})
+
+	getReq := httptest.NewRequest(http.MethodGet, "/formulario", nil)
+	getRec := httptest.NewRecorder()
+	handler(getRec, getReq)
+
+	cookies := getRec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("esperaba 1 cookie, obtuve %d", len(cookies))
+	}
+	token := cookies[0].Value
+	if getRec.Body.String() != token {
+		t.Fatalf("TokenFrom no devolvió el token de la cookie")
+	}
+
+	postSinToken := httptest.NewRequest(http.MethodPost, "/formulario", nil)
+	postSinToken.AddCookie(cookies[0])
+	recSinToken := httptest.NewRecorder()
+	handler(recSinToken, postSinToken)
+	if recSinToken.Code != http.StatusForbidden {
+		t.Fatalf("esperaba 403 sin token enviado, obtuve %d", recSinToken.Code)
+	}
+
+	postConToken := httptest.NewRequest(http.MethodPost, "/formulario", nil)
+	postConToken.AddCookie(cookies[0])
+	postConToken.Header.Set("X-CSRF-Token", token)
+	recConToken := httptest.NewRecorder()
+	handler(recConToken, postConToken)
+	if recConToken.Code != http.StatusOK {
+		t.Fatalf("esperaba 200 con token válido, obtuve %d", recConToken.Code)
+	}
+}
+
+func TestCSRFMiddlewareRechazaTokenAjeno(t *testing.T) {
+	opts := CSRFOptions{Secret: []byte("secreto-de-prueba")}
+	handler := CSRFMiddleware(opts)(func(w http.ResponseWriter, r *http.Request) {})
+
+	getReq := httptest.NewRequest(http.MethodGet, "/formulario", nil)
+	getRec := httptest.NewRecorder()
+	handler(getRec, getReq)
+	cookie := getRec.Result().Cookies()[0]
+
+	postReq := httptest.NewRequest(http.MethodPost, "/formulario", nil)
+	postReq.AddCookie(cookie)
+	postReq.Header.Set("X-CSRF-Token", "token-forjado-sin-firma-valida")
+	rec := httptest.NewRecorder()
+	handler(rec, postReq)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("esperaba 403 con token ajeno, obtuve %d", rec.Code)
+	}
+}
+
+func TestCSRFMiddlewareIgnoraGET(t *testing.T) {
+	opts := CSRFOptions{Secret: []byte("secreto-de-prueba")}
+	handler := CSRFMiddleware(opts)(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/formulario", nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200 en GET sin token, obtuve %d", rec.Code)
+	}
+}
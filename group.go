@@ -0,0 +1,78 @@
+package lumbimux
+
+import (
+	"net/http"
+	"strings"
+)
+
+// unePrefijo une un prefijo de grupo con una ruta o sub-prefijo, normalizando las
+// barras para que "/admin/" + "/users" produzca "/admin/users" en vez de
+// "/admin//users" (lo que haría la ruta inalcanzable, ya que segmentosDeRuta la
+// partiría en un segmento vacío).
+func unePrefijo(prefijo, ruta string) string {
+	prefijo = strings.TrimRight(prefijo, "/")
+	return prefijo + "/" + strings.TrimLeft(ruta, "/")
+}
+
+// groupRouter es un Router que delega en un router raíz, anteponiendo un prefijo a
+// cada ruta registrada y combinando los middlewares heredados del padre con los
+// propios del grupo y los de cada ruta. Permite agrupar, por ejemplo, "/admin/*" bajo
+// una misma pila de middlewares sin repetirla en cada llamada a GET/POST/etc.
+type groupRouter struct {
+	raiz        *LumbiMuxRouter
+	prefijo     string
+	middlewares []Middleware
+}
+
+// Use añade middlewares que se aplican a las rutas registradas a partir de ahora en
+// este grupo (y en los subgrupos que se creen desde él), sin afectar al router padre.
+func (g *groupRouter) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+// Group crea un subgrupo anidado, concatenando el prefijo al de este grupo y
+// heredando su pila de middlewares.
+func (g *groupRouter) Group(prefijo string, middlewares ...Middleware) Router {
+	return &groupRouter{
+		raiz:        g.raiz,
+		prefijo:     unePrefijo(g.prefijo, prefijo),
+		middlewares: append(append([]Middleware{}, g.middlewares...), middlewares...),
+	}
+}
+
+// Handle registra ruta y handler para un método HTTP arbitrario dentro del grupo. Los
+// middlewares del grupo (heredados del padre más los añadidos con Use/Group) viajan
+// como "comunes" y los de esta llamada como "propios": ServeHTTP solo reutiliza los
+// primeros al construir una respuesta sintética de 405/OPTIONS para otro método.
+func (g *groupRouter) Handle(metodo, ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.raiz.registraRuta(metodo, unePrefijo(g.prefijo, ruta), handler, g.middlewares, middlewares)
+}
+
+func (g *groupRouter) GET(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("GET", ruta, handler, middlewares...)
+}
+
+func (g *groupRouter) POST(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("POST", ruta, handler, middlewares...)
+}
+
+func (g *groupRouter) PUT(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("PUT", ruta, handler, middlewares...)
+}
+
+func (g *groupRouter) DELETE(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("DELETE", ruta, handler, middlewares...)
+}
+
+func (g *groupRouter) PATCH(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("PATCH", ruta, handler, middlewares...)
+}
+
+func (g *groupRouter) OPTIONS(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	g.Handle("OPTIONS", ruta, handler, middlewares...)
+}
+
+// ServeHTTP delega en el router raíz, que es quien conoce el árbol de rutas completo.
+func (g *groupRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.raiz.ServeHTTP(w, req)
+}
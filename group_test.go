@@ -0,0 +1,66 @@
+package lumbimux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGroupUnePrefijoSinDobleBarra cubre la regresión de la barra doble: un grupo
+// creado con un prefijo terminado en "/" no debe producir rutas como "/admin//users",
+// que segmentosDeRuta partiría con un segmento vacío y dejaría inalcanzables.
+func TestGroupUnePrefijoSinDobleBarra(t *testing.T) {
+	r := NewLumbiMux()
+	admin := r.Group("/admin/")
+	admin.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200 en /admin/users, obtuve %d", rec.Code)
+	}
+}
+
+// TestGroupEjecutaMiddlewaresHeredadosYPropiosEnOrden cubre que una ruta registrada en
+// un grupo ejecuta, en orden, los middlewares de Use del router raíz, los del propio
+// grupo y por último los pasados solo a esa llamada.
+func TestGroupEjecutaMiddlewaresHeredadosYPropiosEnOrden(t *testing.T) {
+	var orden []string
+	marca := func(nombre string) Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, req *http.Request) {
+				orden = append(orden, nombre)
+				next(w, req)
+			}
+		}
+	}
+
+	r := NewLumbiMux()
+	r.Use(marca("global"))
+	admin := r.Group("/admin", marca("grupo"))
+	admin.GET("/users", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, marca("ruta"))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200, obtuve %d", rec.Code)
+	}
+	esperado := []string{"global", "grupo", "ruta"}
+	if len(orden) != len(esperado) {
+		t.Fatalf("esperaba orden %v, obtuve %v", esperado, orden)
+	}
+	for i, nombre := range esperado {
+		if orden[i] != nombre {
+			t.Errorf("esperaba orden %v, obtuve %v", esperado, orden)
+			break
+		}
+	}
+}
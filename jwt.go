@@ -1,43 +1,137 @@
 package lumbimux
 
 import (
+	"context"
 	"errors"
 	"net/http"
-	"strings"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTMiddleware es un middleware que verifica la validez de un token JWT en la cabecera Authorization de la solicitud.
-func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+// claimsKeyType es el tipo usado como clave de contexto para las claims validadas,
+// evitando colisiones con otras claves de tipo string.
+type claimsKeyType struct{}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validar el método de firma
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("método de firma inválido")
-			}
+var claimsKey = claimsKeyType{}
 
-			// Devolver la clave secreta utilizada para firmar el token
-			return []byte("mi-clave-secreta"), nil
-		})
+// TokenExtractor obtiene el token JWT en bruto de la solicitud, devolviendo una cadena
+// vacía si no lo encuentra en el lugar que le corresponde.
+type TokenExtractor func(r *http.Request) string
 
-		if err != nil {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+// HeaderExtractor extrae el token del header indicado (típicamente "Authorization"),
+// quitando el prefijo "Bearer " si está presente.
+func HeaderExtractor(header string) TokenExtractor {
+	return func(r *http.Request) string {
+		valor := r.Header.Get(header)
+		const prefijo = "Bearer "
+		if len(valor) > len(prefijo) && valor[:len(prefijo)] == prefijo {
+			return valor[len(prefijo):]
 		}
+		return valor
+	}
+}
 
-		if !token.Valid {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+// CookieExtractor extrae el token de la cookie con el nombre indicado.
+func CookieExtractor(nombre string) TokenExtractor {
+	return func(r *http.Request) string {
+		cookie, err := r.Cookie(nombre)
+		if err != nil {
+			return ""
 		}
+		return cookie.Value
+	}
+}
+
+// QueryExtractor extrae el token del parámetro de query indicado.
+func QueryExtractor(parametro string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(parametro)
+	}
+}
+
+// JWTConfig configura NewJWTMiddleware: cómo se obtiene el token de la solicitud, cómo
+// se valida su firma y qué ocurre si la validación falla.
+type JWTConfig struct {
+	// KeyFunc resuelve la clave de verificación a partir del token, como exige
+	// jwt.Parse. Es obligatorio.
+	KeyFunc jwt.Keyfunc
+	// SigningMethods lista los algoritmos aceptados (p.ej. "HS256", "RS256", "EdDSA").
+	// Si está vacío, se acepta únicamente HMAC (HS256/HS384/HS512).
+	SigningMethods []string
+	// Extractors es la cadena de extractores de token a probar en orden; se usa el
+	// primero que devuelva un valor no vacío. Si está vacía, se usa
+	// HeaderExtractor("Authorization").
+	Extractors []TokenExtractor
+	// ErrorHandler se invoca cuando falta el token o la validación falla, permitiendo a
+	// la aplicación personalizar la respuesta en vez de recibir un 401 plano.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+var metodosHMACPorDefecto = []string{"HS256", "HS384", "HS512"}
+
+func (cfg JWTConfig) extractores() []TokenExtractor {
+	if len(cfg.Extractors) > 0 {
+		return cfg.Extractors
+	}
+	return []TokenExtractor{HeaderExtractor("Authorization")}
+}
 
-		next(w, r)
+func (cfg JWTConfig) metodosAceptados() []string {
+	if len(cfg.SigningMethods) > 0 {
+		return cfg.SigningMethods
 	}
+	return metodosHMACPorDefecto
+}
+
+func (cfg JWTConfig) manejaError(w http.ResponseWriter, r *http.Request, err error) {
+	if cfg.ErrorHandler != nil {
+		cfg.ErrorHandler(w, r, err)
+		return
+	}
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// NewJWTMiddleware crea un Middleware que valida un JWT según cfg: extrae el token
+// probando cada extractor de cfg.Extractors en orden, lo valida con cfg.KeyFunc
+// restringido a cfg.SigningMethods, y expone las claims validadas al siguiente handler
+// a través de ClaimsFrom(r.Context()).
+func NewJWTMiddleware(cfg JWTConfig) Middleware {
+	if cfg.KeyFunc == nil {
+		panic("lumbimux: JWTConfig.KeyFunc es obligatorio")
+	}
+
+	extractores := cfg.extractores()
+	metodos := cfg.metodosAceptados()
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var tokenString string
+			for _, extrae := range extractores {
+				if t := extrae(r); t != "" {
+					tokenString = t
+					break
+				}
+			}
+			if tokenString == "" {
+				cfg.manejaError(w, r, errors.New("token no encontrado"))
+				return
+			}
+
+			token, err := jwt.Parse(tokenString, cfg.KeyFunc, jwt.WithValidMethods(metodos))
+			if err != nil || !token.Valid {
+				cfg.manejaError(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey, token.Claims)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// ClaimsFrom devuelve las claims del JWT validadas por un middleware creado con
+// NewJWTMiddleware, si las hay en el contexto.
+func ClaimsFrom(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(jwt.Claims)
+	return claims, ok
 }
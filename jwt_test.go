@@ -0,0 +1,96 @@
+package lumbimux
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func tokenFirmado(t *testing.T, metodo jwt.SigningMethod, clave interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	firmado, err := jwt.NewWithClaims(metodo, claims).SignedString(clave)
+	if err != nil {
+		t.Fatalf("no se pudo firmar el token: %v", err)
+	}
+	return firmado
+}
+
+func TestJWTMiddlewareAceptaYRechazaPorMetodoDeFirma(t *testing.T) {
+	secreto := []byte("clave-compartida")
+	rsaClave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("no se pudo generar la clave RSA: %v", err)
+	}
+
+	handler := NewJWTMiddleware(JWTConfig{
+		KeyFunc: func(token *jwt.Token) (interface{}, error) { return secreto, nil },
+	})(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	hs256 := tokenFirmado(t, jwt.SigningMethodHS256, secreto, jwt.MapClaims{"sub": "ana"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+hs256)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200 con HS256 por defecto, obtuve %d", rec.Code)
+	}
+
+	rs256 := tokenFirmado(t, jwt.SigningMethodRS256, rsaClave, jwt.MapClaims{"sub": "ana"})
+	reqRS := httptest.NewRequest(http.MethodGet, "/", nil)
+	reqRS.Header.Set("Authorization", "Bearer "+rs256)
+	recRS := httptest.NewRecorder()
+	handler(recRS, reqRS)
+	if recRS.Code != http.StatusUnauthorized {
+		t.Fatalf("esperaba 401 al rechazar un token RS256 fuera de SigningMethods, obtuve %d", recRS.Code)
+	}
+}
+
+// TestJWTMiddlewareConfiguracionPersonalizada cubre SigningMethods y Extractors no
+// por defecto, y que las claims validadas llegan al handler vía ClaimsFrom.
+func TestJWTMiddlewareConfiguracionPersonalizada(t *testing.T) {
+	rsaClave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("no se pudo generar la clave RSA: %v", err)
+	}
+
+	var claimsVistas jwt.Claims
+	var ok bool
+	handler := NewJWTMiddleware(JWTConfig{
+		KeyFunc:        func(token *jwt.Token) (interface{}, error) { return &rsaClave.PublicKey, nil },
+		SigningMethods: []string{"RS256"},
+		Extractors:     []TokenExtractor{CookieExtractor("token")},
+	})(func(w http.ResponseWriter, r *http.Request) {
+		claimsVistas, ok = ClaimsFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rs256 := tokenFirmado(t, jwt.SigningMethodRS256, rsaClave, jwt.MapClaims{"sub": "beto"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "token", Value: rs256})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200 con el extractor de cookie y RS256, obtuve %d", rec.Code)
+	}
+	if !ok {
+		t.Fatal("ClaimsFrom no devolvió claims tras una validación correcta")
+	}
+	claims, _ := claimsVistas.(jwt.MapClaims)
+	if claims["sub"] != "beto" {
+		t.Errorf("esperaba sub=beto, obtuve %v", claims["sub"])
+	}
+}
+
+func TestClaimsFromSinMiddlewareDevuelveFalse(t *testing.T) {
+	if _, ok := ClaimsFrom(context.Background()); ok {
+		t.Fatal("ClaimsFrom debería devolver ok=false sin que haya corrido un middleware JWT")
+	}
+}
@@ -1,8 +1,11 @@
 package lumbimux
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 // Middleware es una función que toma un http.HandlerFunc y devuelve otro http.HandlerFunc.
@@ -16,87 +19,162 @@ type Router interface {
 	DELETE(ruta string, handler http.HandlerFunc, middlewares ...Middleware)
 	PATCH(ruta string, handler http.HandlerFunc, middlewares ...Middleware)
 	OPTIONS(ruta string, handler http.HandlerFunc, middlewares ...Middleware)
+	// Handle registra ruta para un método HTTP arbitrario, incluidas extensiones como
+	// las de WebDAV/CalDAV (PROPFIND, REPORT, MKCALENDAR...) que no tienen un método
+	// dedicado en esta interfaz. GET/POST/PUT/DELETE/PATCH/OPTIONS delegan en él.
+	Handle(metodo, ruta string, handler http.HandlerFunc, middlewares ...Middleware)
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
+
+	// Use añade middlewares que se aplican a las rutas registradas a partir de este
+	// momento en este router y en los grupos que se creen después desde él. Los grupos
+	// creados antes de la llamada no los reciben, y Use sobre un grupo no afecta al padre.
+	Use(middlewares ...Middleware)
+	// Group devuelve un subrouter que antepone prefijo a todas sus rutas y hereda la
+	// pila de middlewares del router padre, sin afectar a las rutas ya registradas en él.
+	Group(prefijo string, middlewares ...Middleware) Router
 }
 
-// LumbiMuxRouter es una estructura que implementa la interfaz Router.
+// LumbiMuxRouter es una estructura que implementa la interfaz Router. Las rutas se
+// indexan por método HTTP y, dentro de cada método, se resuelven mediante un trie que
+// soporta segmentos estáticos, parámetros (":id") y comodines ("*resto"), de modo que
+// ServeHTTP resuelve en un número de pasos proporcional a los segmentos de la ruta.
 type LumbiMuxRouter struct {
-	reglas      map[string]map[string]http.HandlerFunc
-	middlewares map[string][]Middleware
+	rutas  map[string]*nodo
+	global []Middleware
 }
 
 // NewLumbiMux crea una nueva instancia de LumbiMuxRouter.
 func NewLumbiMux() Router {
 	return &LumbiMuxRouter{
-		reglas:      make(map[string]map[string]http.HandlerFunc),
-		middlewares: make(map[string][]Middleware),
+		rutas: make(map[string]*nodo),
+	}
+}
+
+// Use añade middlewares que se aplican a todas las rutas registradas a partir de ahora
+// en este router, por delante de los middlewares propios de cada ruta.
+func (r *LumbiMuxRouter) Use(middlewares ...Middleware) {
+	r.global = append(r.global, middlewares...)
+}
+
+// Group devuelve un subrouter que antepone prefijo a sus rutas y hereda los middlewares
+// globales registrados hasta ahora en este router, más los que se le indiquen.
+func (r *LumbiMuxRouter) Group(prefijo string, middlewares ...Middleware) Router {
+	return &groupRouter{
+		raiz:        r,
+		prefijo:     strings.TrimRight(prefijo, "/"),
+		middlewares: append(append([]Middleware{}, r.global...), middlewares...),
+	}
+}
+
+// registraRuta añade una ruta al trie del método indicado, creando el árbol si es la
+// primera ruta registrada para ese método. comunes son los middlewares de Use/Group que
+// aplican a todos los métodos de esta ruta; propios son los pasados solo a esta llamada.
+func (r *LumbiMuxRouter) registraRuta(metodo, ruta string, handler http.HandlerFunc, comunes, propios []Middleware) {
+	if r.rutas[metodo] == nil {
+		r.rutas[metodo] = nuevoNodo()
 	}
+	r.rutas[metodo].registra(segmentosDeRuta(ruta), handler, comunes, propios)
+}
+
+// Handle registra ruta y handler para un método HTTP arbitrario.
+func (r *LumbiMuxRouter) Handle(metodo, ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
+	r.registraRuta(metodo, ruta, handler, r.global, middlewares)
 }
 
 // GET registra una ruta y handler HTTP GET.
 func (r *LumbiMuxRouter) GET(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["GET"] == nil {
-		r.reglas["GET"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["GET"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["GET:"+ruta] = middlewares
+	r.Handle("GET", ruta, handler, middlewares...)
 }
 
 // POST registra una ruta y handler HTTP POST.
 func (r *LumbiMuxRouter) POST(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["POST"] == nil {
-		r.reglas["POST"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["POST"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["POST:"+ruta] = middlewares
+	r.Handle("POST", ruta, handler, middlewares...)
 }
 
 // PUT registra una ruta y handler HTTP PUT.
 func (r *LumbiMuxRouter) PUT(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["PUT"] == nil {
-		r.reglas["PUT"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["PUT"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["PUT:"+ruta] = middlewares
+	r.Handle("PUT", ruta, handler, middlewares...)
 }
 
 // DELETE registra una ruta y handler HTTP DELETE.
 func (r *LumbiMuxRouter) DELETE(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["DELETE"] == nil {
-		r.reglas["DELETE"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["DELETE"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["DELETE:"+ruta] = middlewares
+	r.Handle("DELETE", ruta, handler, middlewares...)
 }
 
 // PATCH registra una ruta y handler HTTP PATCH.
 func (r *LumbiMuxRouter) PATCH(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["PATCH"] == nil {
-		r.reglas["PATCH"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["PATCH"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["PATCH:"+ruta] = middlewares
+	r.Handle("PATCH", ruta, handler, middlewares...)
 }
 
 // OPTIONS registra una ruta y handler HTTP OPTIONS.
 func (r *LumbiMuxRouter) OPTIONS(ruta string, handler http.HandlerFunc, middlewares ...Middleware) {
-	if r.reglas["OPTIONS"] == nil {
-		r.reglas["OPTIONS"] = make(map[string]http.HandlerFunc)
-	}
-	r.reglas["OPTIONS"][ruta] = anadeMiddleware(handler, middlewares...)
-	r.middlewares["OPTIONS:"+ruta] = middlewares
+	r.Handle("OPTIONS", ruta, handler, middlewares...)
 }
 
-// ServeHTTP maneja las solicitudes HTTP entrantes mediante la coincidencia del método de solicitud y la ruta de URL con un handler registrado.
+// ServeHTTP maneja las solicitudes HTTP entrantes mediante la coincidencia del método de
+// solicitud y la ruta de URL con un handler registrado. Si la ruta coincidente tenía
+// parámetros, se exponen a través de Params(req) mediante el contexto de la solicitud.
+// Cuando la ruta existe pero no para ese método, responde 405 Method Not Allowed con un
+// header Allow; si además el método es OPTIONS y no se registró un handler explícito
+// para él, responde automáticamente con los métodos permitidos. En ambos casos la
+// respuesta se envuelve únicamente con los middlewares "comunes" (los de Use/Group) de
+// una de las rutas coincidentes, nunca con los propios de un método concreto: un
+// JWTMiddleware o CSRFMiddleware puesto solo en el GET de la ruta está pensado para
+// proteger ese GET, no para decidir si otro método distinto puede ver un 405.
 func (r *LumbiMuxRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	if handler, ok := r.reglas[req.Method][req.URL.Path]; ok {
-		for _, middleware := range r.middlewares[req.Method+":"+req.URL.Path] {
-			handler = middleware(handler)
+	segmentos := segmentosDeRuta(req.URL.Path)
+
+	if arbol, ok := r.rutas[req.Method]; ok {
+		if handler, params := arbol.busca(segmentos); handler != nil {
+			if params != nil {
+				req = req.WithContext(context.WithValue(req.Context(), paramsKey, params))
+			}
+			handler(w, req)
+			return
 		}
-		handler(w, req)
+	}
+
+	permitidos, comunes := r.metodosPermitidos(segmentos)
+	if len(permitidos) == 0 {
+		http.NotFound(w, req)
 		return
 	}
-	http.NotFound(w, req)
+
+	terminal := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Allow", strings.Join(permitidos, ", "))
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+
+	handler := anadeMiddleware(terminal, comunes...)
+	handler(w, req)
+}
+
+// metodosPermitidos devuelve, ordenados alfabéticamente, los métodos HTTP que tienen una
+// ruta registrada que coincide con segmentos, junto con los middlewares "comunes" (de
+// Use/Group) de una de ellas, para reutilizarlos en la respuesta sintética de
+// ServeHTTP sin arrastrar los middlewares propios de ningún método en particular.
+func (r *LumbiMuxRouter) metodosPermitidos(segmentos []string) ([]string, []Middleware) {
+	nodos := make(map[string]*nodo)
+	for metodo, arbol := range r.rutas {
+		if nodoFinal, _ := arbol.buscaNodo(segmentos); nodoFinal != nil {
+			nodos[metodo] = nodoFinal
+		}
+	}
+	permitidos := make([]string, 0, len(nodos))
+	for metodo := range nodos {
+		permitidos = append(permitidos, metodo)
+	}
+	sort.Strings(permitidos)
+
+	var comunes []Middleware
+	if len(permitidos) > 0 {
+		comunes = nodos[permitidos[0]].comunes
+	}
+	return permitidos, comunes
 }
 
 // anadeMiddleware toma un http.HandlerFunc y una lista de Middleware y devuelve un nuevo http.HandlerFunc que aplica los middleware a la función original.
@@ -0,0 +1,154 @@
+package lumbimux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestServeHTTPDevuelve405ConAllow(t *testing.T) {
+	r := NewLumbiMux()
+	r.GET("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodPost, "/recurso", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("esperaba 405, obtuve %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != "GET" {
+		t.Errorf("esperaba Allow: GET, obtuve %q", rec.Header().Get("Allow"))
+	}
+}
+
+func TestServeHTTPAutoOPTIONSListaMetodosPermitidos(t *testing.T) {
+	r := NewLumbiMux()
+	r.GET("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+	r.POST("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/recurso", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("esperaba 204, obtuve %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("esperaba Allow: GET, POST, obtuve %q", rec.Header().Get("Allow"))
+	}
+}
+
+// TestServeHTTPAutoOPTIONSAplicaMiddlewareGlobal cubre el caso en que CORSMiddleware se
+// registra con Use y nunca hay un handler OPTIONS explícito: el preflight automático
+// debe seguir pasando por esa pila de middlewares para que lleven las cabeceras CORS.
+func TestServeHTTPAutoOPTIONSAplicaMiddlewareGlobal(t *testing.T) {
+	r := NewLumbiMux()
+	r.Use(CORSMiddleware(CORSOptions{
+		AllowOrigins: []string{"https://ejemplo.com"},
+		AllowMethods: []string{"GET"},
+	}))
+	r.GET("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/recurso", nil)
+	req.Header.Set("Origin", "https://ejemplo.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("esperaba 204, obtuve %d", rec.Code)
+	}
+	if origen := rec.Header().Get("Access-Control-Allow-Origin"); origen != "https://ejemplo.com" {
+		t.Errorf("CORSMiddleware no se aplicó al preflight automático: Access-Control-Allow-Origin=%q", origen)
+	}
+	if metodos := rec.Header().Get("Access-Control-Allow-Methods"); metodos != "GET" {
+		t.Errorf("esperaba Access-Control-Allow-Methods: GET, obtuve %q", metodos)
+	}
+}
+
+// TestServeHTTPNoAplicaMiddlewarePropioDeOtroMetodo cubre el caso en que un método
+// sí registrado para la ruta tiene un middleware propio (no de Use/Group) que no
+// debería interferir con la respuesta sintética que ServeHTTP da a un método distinto:
+// ni el JWTMiddleware puesto solo en el GET ni el CSRFMiddleware puesto solo en el POST
+// deben convertir el 405 de un PUT en un 401 o un 403.
+func TestServeHTTPNoAplicaMiddlewarePropioDeOtroMetodo(t *testing.T) {
+	r := NewLumbiMux()
+	r.GET("/secreto", func(w http.ResponseWriter, req *http.Request) {}, NewJWTMiddleware(JWTConfig{
+		KeyFunc: func(t *jwt.Token) (interface{}, error) { return []byte("clave"), nil },
+	}))
+	r.POST("/secreto", func(w http.ResponseWriter, req *http.Request) {}, CSRFMiddleware(CSRFOptions{
+		Secret: []byte("otra-clave"),
+	}))
+
+	// PUT no está registrado: ni el JWTMiddleware del GET ni el CSRFMiddleware del POST
+	// deben ejecutarse, y la respuesta debe ser el 405 genérico con ambos en Allow.
+	req := httptest.NewRequest(http.MethodPut, "/secreto", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("esperaba 405, obtuve %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("esperaba Allow: GET, POST, obtuve %q", rec.Header().Get("Allow"))
+	}
+}
+
+// TestHandleRegistraMetodoArbitrario cubre que Handle registra y despacha correctamente
+// un método HTTP sin método dedicado en la interfaz Router (p.ej. PROPFIND de WebDAV), y
+// que el 405/Allow sintético de ServeHTTP lo lista junto a los demás métodos de la ruta.
+func TestHandleRegistraMetodoArbitrario(t *testing.T) {
+	const propfind = "PROPFIND"
+
+	r := NewLumbiMux()
+	r.Handle(propfind, "/recurso", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+	})
+	r.GET("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(propfind, "/recurso", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("esperaba %d al despachar PROPFIND, obtuve %d", http.StatusMultiStatus, rec.Code)
+	}
+
+	// POST no está registrado para /recurso: el 405 debe listar PROPFIND junto a GET.
+	reqPost := httptest.NewRequest(http.MethodPost, "/recurso", nil)
+	recPost := httptest.NewRecorder()
+	r.ServeHTTP(recPost, reqPost)
+	if recPost.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("esperaba 405, obtuve %d", recPost.Code)
+	}
+	if recPost.Header().Get("Allow") != "GET, PROPFIND" {
+		t.Errorf("esperaba Allow: GET, PROPFIND, obtuve %q", recPost.Header().Get("Allow"))
+	}
+}
+
+func TestServeHTTPAutoOPTIONSIgnoraOrigenNoPermitido(t *testing.T) {
+	r := NewLumbiMux()
+	r.Use(CORSMiddleware(CORSOptions{
+		AllowOrigins: []string{"https://ejemplo.com"},
+		AllowMethods: []string{"GET"},
+	}))
+	r.GET("/recurso", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodOptions, "/recurso", nil)
+	req.Header.Set("Origin", "https://atacante.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("no debería haber Access-Control-Allow-Origin para un origen no permitido")
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("esperaba 204 del auto-OPTIONS, obtuve %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != "GET" {
+		t.Errorf("esperaba Allow: GET, obtuve %q", rec.Header().Get("Allow"))
+	}
+}
@@ -0,0 +1,133 @@
+package lumbimux
+
+import (
+	"net/http"
+	"strings"
+)
+
+// paramsKeyType es el tipo usado como clave de contexto para los parámetros de ruta,
+// evitando colisiones con otras claves de tipo string.
+type paramsKeyType struct{}
+
+var paramsKey = paramsKeyType{}
+
+// nodo es un nodo de un árbol de rutas (trie) que resuelve segmentos estáticos,
+// parámetros con nombre (":id") y comodines ("*resto").
+type nodo struct {
+	hijos         map[string]*nodo
+	paramHijo     *nodo
+	paramNombre   string
+	comodinHijo   *nodo
+	comodinNombre string
+	handler       http.HandlerFunc
+	// comunes es la parte de la pila de middlewares de esta ruta que viene de Use/Group
+	// y por tanto aplica a todos los métodos registrados bajo este mismo prefijo, a
+	// diferencia de los middlewares propios de esta ruta en concreto (p.ej. un
+	// JWTMiddleware puesto solo en el GET). Es lo único que ServeHTTP reutiliza al
+	// construir la respuesta sintética de 405/OPTIONS para otro método.
+	comunes []Middleware
+}
+
+// nuevoNodo crea un nodo de trie vacío listo para recibir rutas.
+func nuevoNodo() *nodo {
+	return &nodo{hijos: make(map[string]*nodo)}
+}
+
+// segmentosDeRuta descompone una ruta en sus segmentos, ignorando las barras de inicio y fin.
+func segmentosDeRuta(ruta string) []string {
+	ruta = strings.Trim(ruta, "/")
+	if ruta == "" {
+		return nil
+	}
+	return strings.Split(ruta, "/")
+}
+
+// registra inserta un handler en el trie siguiendo los segmentos de la ruta, creando
+// los nodos intermedios que hagan falta. El handler final se envuelve con comunes
+// (middlewares de Use/Group, compartidos por todos los métodos de esta ruta) seguidos
+// de propios (middlewares pasados solo a esta llamada de GET/POST/.../Handle).
+func (n *nodo) registra(segmentos []string, handler http.HandlerFunc, comunes, propios []Middleware) {
+	actual := n
+	for _, segmento := range segmentos {
+		switch {
+		case strings.HasPrefix(segmento, ":"):
+			if actual.paramHijo == nil {
+				actual.paramHijo = nuevoNodo()
+			}
+			actual.paramHijo.paramNombre = segmento[1:]
+			actual = actual.paramHijo
+		case strings.HasPrefix(segmento, "*"):
+			if actual.comodinHijo == nil {
+				actual.comodinHijo = nuevoNodo()
+			}
+			actual.comodinHijo.comodinNombre = segmento[1:]
+			actual = actual.comodinHijo
+		default:
+			hijo, ok := actual.hijos[segmento]
+			if !ok {
+				hijo = nuevoNodo()
+				actual.hijos[segmento] = hijo
+			}
+			actual = hijo
+		}
+	}
+	todos := append(append([]Middleware{}, comunes...), propios...)
+	actual.handler = anadeMiddleware(handler, todos...)
+	actual.comunes = comunes
+}
+
+// buscaNodo resuelve los segmentos de una ruta entrante contra el trie, devolviendo el
+// nodo con handler registrado y los parámetros extraídos de la URL, si los hay. Si la
+// rama estática no lleva a un handler para el resto de la ruta, se retrocede y se
+// prueba con paramHijo y, en su defecto, comodinHijo, para que rutas como "/a/:x/d"
+// sigan siendo alcanzables aunque "/a/b" también esté registrada como segmento estático.
+func (n *nodo) buscaNodo(segmentos []string) (*nodo, map[string]string) {
+	if len(segmentos) == 0 {
+		if n.handler == nil {
+			return nil, nil
+		}
+		return n, nil
+	}
+
+	segmento, resto := segmentos[0], segmentos[1:]
+
+	if hijo, ok := n.hijos[segmento]; ok {
+		if final, params := hijo.buscaNodo(resto); final != nil {
+			return final, params
+		}
+	}
+
+	if n.paramHijo != nil {
+		if final, params := n.paramHijo.buscaNodo(resto); final != nil {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[n.paramHijo.paramNombre] = segmento
+			return final, params
+		}
+	}
+
+	if n.comodinHijo != nil && n.comodinHijo.handler != nil {
+		return n.comodinHijo, map[string]string{
+			n.comodinHijo.comodinNombre: strings.Join(segmentos, "/"),
+		}
+	}
+
+	return nil, nil
+}
+
+// busca es un atajo sobre buscaNodo para cuando solo hace falta el handler resuelto.
+func (n *nodo) busca(segmentos []string) (http.HandlerFunc, map[string]string) {
+	final, params := n.buscaNodo(segmentos)
+	if final == nil {
+		return nil, nil
+	}
+	return final.handler, params
+}
+
+// Params devuelve los parámetros de ruta extraídos para la solicitud actual. El mapa
+// devuelto es nil si la ruta coincidente no tenía parámetros.
+func Params(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(paramsKey).(map[string]string)
+	return params
+}
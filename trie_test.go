@@ -0,0 +1,73 @@
+package lumbimux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouterParamsYWildcards(t *testing.T) {
+	r := NewLumbiMux()
+
+	r.GET("/users/:id", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(Params(req)["id"]))
+	})
+	r.GET("/files/*resto", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(Params(req)["resto"]))
+	})
+
+	casos := []struct {
+		ruta     string
+		esperado string
+	}{
+		{"/users/42", "42"},
+		{"/files/a/b/c.txt", "a/b/c.txt"},
+	}
+
+	for _, c := range casos {
+		req := httptest.NewRequest(http.MethodGet, c.ruta, nil)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Body.String() != c.esperado {
+			t.Errorf("ruta %q: esperaba %q, obtuve %q", c.ruta, c.esperado, rec.Body.String())
+		}
+	}
+}
+
+// TestBuscaRetrocedeDeEstaticoAParametro cubre el caso en que una rama estática
+// registrada no resuelve el resto de la ruta: el matcher debe retroceder y probar la
+// rama de parámetro en vez de devolver 404 directamente.
+func TestBuscaRetrocedeDeEstaticoAParametro(t *testing.T) {
+	r := NewLumbiMux()
+
+	r.GET("/a/b/c", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("estatico"))
+	})
+	r.GET("/a/:x/d", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("param:" + Params(req)["x"]))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/a/b/d", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("esperaba 200, obtuve %d", rec.Code)
+	}
+	if rec.Body.String() != "param:b" {
+		t.Errorf("esperaba %q, obtuve %q", "param:b", rec.Body.String())
+	}
+}
+
+func TestRutaNoRegistradaDevuelve404(t *testing.T) {
+	r := NewLumbiMux()
+	r.GET("/a/b/c", func(w http.ResponseWriter, req *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/a/b/x", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("esperaba 404, obtuve %d", rec.Code)
+	}
+}